@@ -0,0 +1,245 @@
+package http
+
+import (
+	"fmt"
+	"strings"
+
+	utls "github.com/refraction-networking/utls"
+)
+
+// clientHelloIDs maps the lowercase labels SpecFromClientHelloID accepts to
+// the uTLS ClientHelloID they resolve to. Labels mirror the browser/version
+// naming uTLS itself uses (e.g. "chrome_102", "firefox_102", "ios_14"),
+// keeping callers insulated from the HelloXxx_Yyy identifier names.
+var clientHelloIDs = map[string]utls.ClientHelloID{
+	"chrome_102":     utls.HelloChrome_102,
+	"chrome_106":     utls.HelloChrome_106_Shuffle,
+	"chrome_112_psk": utls.HelloChrome_112_PSK_Shuf,
+	"chrome_115_psk": utls.HelloChrome_115_PQ_PSK,
+	"chrome_120":     utls.HelloChrome_120,
+	"chrome_131":     utls.HelloChrome_131,
+	"firefox_102":    utls.HelloFirefox_102,
+	"firefox_105":    utls.HelloFirefox_105,
+	"firefox_120":    utls.HelloFirefox_120,
+	"ios_14":         utls.HelloIOS_14,
+	"safari_16":      utls.HelloSafari_16_0,
+}
+
+// preShuffledClientHelloIDs are clientHelloIDs whose utls.UTLSIdToSpec
+// already randomizes the extension order internally, via
+// utls.ShuffleChromeTLSExtensions seeded from crypto/rand, before
+// SpecFromClientHelloID ever sees the spec. Applying this package's own
+// deterministic shuffle on top can't make the result reproducible: the
+// input order it shuffles is different on every call.
+//
+// Every entry in clientHelloIDs needs auditing against uTLS's
+// utlsIdToSpec: as of refraction-networking/utls v1.7.0, HelloChrome_120
+// and HelloChrome_131 shuffle too, alongside chrome_106, chrome_112_psk and
+// chrome_115_psk. Any future
+// clientHelloIDs addition whose case in utls's u_parrots.go builds its
+// Extensions with ShuffleChromeTLSExtensions belongs here.
+var preShuffledClientHelloIDs = map[string]bool{
+	"chrome_106":     true,
+	"chrome_112_psk": true,
+	"chrome_115_psk": true,
+	"chrome_120":     true,
+	"chrome_131":     true,
+}
+
+// SpecFromClientHelloID resolves a named browser preset such as "chrome_102"
+// or "firefox_102" to a uTLS ClientHelloSpec, delegating to
+// utls.UTLSIdToSpec where possible. This lets a caller pin a browser
+// fingerprint by version without hunting down a JA3 string, and keeps them
+// insulated from ordering/GREASE subtleties that JA3 loses.
+//
+// Presets ending in "_psk" describe the ClientHello Chrome sends when
+// resuming a TLS 1.3 session. JA3 discards the pre_shared_key extension
+// entirely, so for these presets the returned spec carries a
+// UtlsPreSharedKeyExtension placed at the very end of the extension list,
+// and GetSessionID is left unset so uTLS can fill it in from a cached
+// session ticket.
+func SpecFromClientHelloID(id string, ext *TLSExtensions) (*utls.ClientHelloSpec, error) {
+	helloID, ok := clientHelloIDs[strings.ToLower(id)]
+	if !ok {
+		return nil, raiseExtensionError(id)
+	}
+
+	spec, err := utls.UTLSIdToSpec(helloID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ext != nil {
+		applyTLSExtensions(&spec, ext)
+	}
+
+	if strings.HasSuffix(strings.ToLower(id), "_psk") {
+		movePreSharedKeyToEnd(&spec)
+		spec.GetSessionID = nil
+	}
+
+	if ext != nil && ext.ShuffleExtensions {
+		if preShuffledClientHelloIDs[strings.ToLower(id)] {
+			return nil, fmt.Errorf("chttp: %s already randomizes its extension order inside uTLS via crypto/rand; ShuffleSeed cannot reproduce a fixed hello for it", id)
+		}
+		spec.Extensions = shuffleExtensions(spec.Extensions, ext.shuffleSeedOrRandom())
+	}
+
+	return &spec, nil
+}
+
+// applyTLSExtensions overlays the caller-supplied extension overrides onto a
+// preset spec, the same fields StringToSpec honours for JA3-derived specs.
+func applyTLSExtensions(spec *utls.ClientHelloSpec, ext *TLSExtensions) {
+	replacements := map[string]utls.TLSExtension{}
+	if ext.SupportedSignatureAlgorithms != nil {
+		replacements["13"] = ext.SupportedSignatureAlgorithms
+	}
+	if ext.CertCompressionAlgo != nil {
+		replacements["27"] = ext.CertCompressionAlgo
+	}
+	if ext.RecordSizeLimit != nil {
+		replacements["28"] = ext.RecordSizeLimit
+	}
+	if ext.DelegatedCredentials != nil {
+		replacements["34"] = ext.DelegatedCredentials
+	}
+	if ext.DelegatedCredentialSchemes != nil {
+		replacements["34"] = &utls.DelegatedCredentialsExtension{SupportedSignatureAlgorithms: ext.DelegatedCredentialSchemes}
+	}
+	if ext.SupportedVersions != nil {
+		replacements["43"] = ext.SupportedVersions
+	}
+	if ext.PSKKeyExchangeModes != nil {
+		replacements["45"] = ext.PSKKeyExchangeModes
+	}
+	if ext.SignatureAlgorithmsCert != nil {
+		replacements["50"] = ext.SignatureAlgorithmsCert
+	}
+	if ext.KeyShareCurves != nil {
+		replacements["51"] = ext.KeyShareCurves
+	}
+	for i, e := range spec.Extensions {
+		if id, ok := extensionID(e); ok {
+			if r, ok := replacements[id]; ok {
+				spec.Extensions[i] = r
+			}
+		}
+	}
+
+	if ext.KyberEnabled != nil {
+		applyKyberOverride(spec, *ext.KyberEnabled)
+	}
+}
+
+// applyKyberOverride adds or removes the X25519Kyber768Draft00 post-quantum
+// hybrid group from a preset's SupportedCurvesExtension and
+// KeyShareExtension, so KyberEnabled behaves the same way for named
+// presets as it already does for StringToSpec's JA3 path.
+func applyKyberOverride(spec *utls.ClientHelloSpec, enabled bool) {
+	for _, e := range spec.Extensions {
+		switch ex := e.(type) {
+		case *utls.SupportedCurvesExtension:
+			ex.Curves = setKyberCurve(ex.Curves, enabled)
+		case *utls.KeyShareExtension:
+			ex.KeyShares = setKyberKeyShare(ex.KeyShares, enabled)
+		}
+	}
+}
+
+// setKyberCurve inserts or removes X25519Kyber768Draft00 from curves,
+// leaving a leading GREASE placeholder in front of it as StringToSpec does.
+func setKyberCurve(curves []utls.CurveID, enabled bool) []utls.CurveID {
+	idx := -1
+	for i, c := range curves {
+		if c == utls.X25519Kyber768Draft00 {
+			idx = i
+			break
+		}
+	}
+	if !enabled {
+		if idx == -1 {
+			return curves
+		}
+		return append(curves[:idx], curves[idx+1:]...)
+	}
+	if idx != -1 {
+		return curves
+	}
+	insertAt := 0
+	if len(curves) > 0 && curves[0] == utls.CurveID(utls.GREASE_PLACEHOLDER) {
+		insertAt = 1
+	}
+	out := make([]utls.CurveID, 0, len(curves)+1)
+	out = append(out, curves[:insertAt]...)
+	out = append(out, utls.X25519Kyber768Draft00)
+	return append(out, curves[insertAt:]...)
+}
+
+// setKyberKeyShare inserts or removes the X25519Kyber768Draft00 key share
+// from shares, leaving a leading GREASE placeholder in front of it as
+// StringToSpec does.
+func setKyberKeyShare(shares []utls.KeyShare, enabled bool) []utls.KeyShare {
+	idx := -1
+	for i, s := range shares {
+		if s.Group == utls.X25519Kyber768Draft00 {
+			idx = i
+			break
+		}
+	}
+	if !enabled {
+		if idx == -1 {
+			return shares
+		}
+		return append(shares[:idx], shares[idx+1:]...)
+	}
+	if idx != -1 {
+		return shares
+	}
+	insertAt := 0
+	if len(shares) > 0 && shares[0].Group == utls.CurveID(utls.GREASE_PLACEHOLDER) {
+		insertAt = 1
+	}
+	out := make([]utls.KeyShare, 0, len(shares)+1)
+	out = append(out, shares[:insertAt]...)
+	out = append(out, utls.KeyShare{Group: utls.X25519Kyber768Draft00})
+	return append(out, shares[insertAt:]...)
+}
+
+// extensionID returns the genMap-style numeric id for the extension types
+// applyTLSExtensions knows how to override.
+func extensionID(e utls.TLSExtension) (string, bool) {
+	switch e.(type) {
+	case *utls.SignatureAlgorithmsExtension:
+		return "13", true
+	case *utls.UtlsCompressCertExtension:
+		return "27", true
+	case *utls.FakeRecordSizeLimitExtension:
+		return "28", true
+	case *utls.DelegatedCredentialsExtension:
+		return "34", true
+	case *utls.SupportedVersionsExtension:
+		return "43", true
+	case *utls.PSKKeyExchangeModesExtension:
+		return "45", true
+	case *utls.SignatureAlgorithmsCertExtension:
+		return "50", true
+	case *utls.KeyShareExtension:
+		return "51", true
+	default:
+		return "", false
+	}
+}
+
+// movePreSharedKeyToEnd relocates the pre_shared_key extension, if present,
+// to the end of the extension list. TLS 1.3 requires pre_shared_key to be
+// the last extension in the ClientHello.
+func movePreSharedKeyToEnd(spec *utls.ClientHelloSpec) {
+	for i, e := range spec.Extensions {
+		if psk, ok := e.(*utls.UtlsPreSharedKeyExtension); ok {
+			spec.Extensions = append(spec.Extensions[:i], spec.Extensions[i+1:]...)
+			spec.Extensions = append(spec.Extensions, psk)
+			return
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package http
+
+import "testing"
+
+func TestSpecFromClientHelloIDUnknownPreset(t *testing.T) {
+	if _, err := SpecFromClientHelloID("not_a_browser", nil); err == nil {
+		t.Fatal("expected an error for an unknown preset")
+	}
+}
+
+func TestSpecFromClientHelloIDRejectsShuffleOnPreShuffledPresets(t *testing.T) {
+	for id := range preShuffledClientHelloIDs {
+		ext := &TLSExtensions{ShuffleExtensions: true}
+		if _, err := SpecFromClientHelloID(id, ext); err == nil {
+			t.Errorf("SpecFromClientHelloID(%q) with ShuffleExtensions = nil error, want an error since uTLS already shuffles it internally", id)
+		}
+	}
+}
+
+func TestSpecFromClientHelloIDShufflesNonPreShuffledPreset(t *testing.T) {
+	ext := &TLSExtensions{ShuffleExtensions: true, ShuffleSeed: [32]byte{1}}
+	if _, ok := preShuffledClientHelloIDs["chrome_102"]; ok {
+		t.Fatal("test assumes chrome_102 is not pre-shuffled")
+	}
+	if _, err := SpecFromClientHelloID("chrome_102", ext); err != nil {
+		t.Fatalf("SpecFromClientHelloID(chrome_102) with ShuffleExtensions = %v, want no error", err)
+	}
+}
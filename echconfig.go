@@ -0,0 +1,192 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+
+	utls "github.com/refraction-networking/utls"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// ConfigureECH sets cfg.EncryptedClientHelloConfigList from
+// tlsExtensions.ECHConfigList, if set. uTLS negotiates real ECH entirely
+// through this Config field: at handshake time it swaps the
+// GREASEEncryptedClientHelloExtension already present in the spec that
+// StringToSpec/SpecFromClientHelloID produced for the real, HPKE-encrypted
+// encrypted_client_hello extension, computed from this ECHConfigList. Call
+// it on the *utls.Config used alongside that spec to dial; it's a no-op if
+// ECHConfigList is unset.
+func (tlsExtensions *TLSExtensions) ConfigureECH(cfg *utls.Config) {
+	if tlsExtensions == nil || tlsExtensions.ECHConfigList == nil {
+		return
+	}
+	cfg.EncryptedClientHelloConfigList = tlsExtensions.ECHConfigList
+}
+
+// typeHTTPS is the DNS RR type for HTTPS records (RFC 9460). dnsmessage
+// doesn't define a constant for it, so it's declared here.
+const typeHTTPS dnsmessage.Type = 65
+
+// svcParamKeyECH is the SvcParamKey carrying the ECHConfigList inside an
+// HTTPS record's SvcParams (RFC 9460 / draft-ietf-tls-svcb-ech).
+const svcParamKeyECH = 5
+
+// FetchECHConfigList resolves the ECHConfigList published in host's DNS
+// HTTPS record, for use as TLSExtensions.ECHConfigList. It returns an error
+// if the record has no "ech" SvcParam, or if the TargetName in the record
+// uses name compression, since the raw RDATA parsed here has no access to
+// the rest of the message to resolve compression pointers against.
+func FetchECHConfigList(ctx context.Context, host string) ([]byte, error) {
+	resolver, err := systemResolver()
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := dnsmessage.NewName(dns0Fqdn(host))
+	if err != nil {
+		return nil, err
+	}
+
+	query := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: uint16(rand.Intn(1 << 16)), RecursionDesired: true},
+		Questions: []dnsmessage.Question{
+			{Name: name, Type: typeHTTPS, Class: dnsmessage.ClassINET},
+		},
+	}
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "udp", resolver)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(packed); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return echConfigListFromResponse(buf[:n])
+}
+
+func echConfigListFromResponse(resp []byte) ([]byte, error) {
+	var parser dnsmessage.Parser
+	if _, err := parser.Start(resp); err != nil {
+		return nil, err
+	}
+	if err := parser.SkipAllQuestions(); err != nil {
+		return nil, err
+	}
+
+	for {
+		hdr, err := parser.AnswerHeader()
+		if errors.Is(err, dnsmessage.ErrSectionDone) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Type != typeHTTPS {
+			if err := parser.SkipAnswer(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		res, err := parser.UnknownResource()
+		if err != nil {
+			return nil, err
+		}
+		return echConfigListFromSvcRecord(res.Data)
+	}
+
+	return nil, raiseExtensionError("no HTTPS record found")
+}
+
+// echConfigListFromSvcRecord parses an HTTPS record's RDATA (SvcPriority,
+// TargetName, SvcParams) and returns the value of its "ech" SvcParam.
+func echConfigListFromSvcRecord(data []byte) ([]byte, error) {
+	if len(data) < 2 {
+		return nil, raiseExtensionError("truncated HTTPS record")
+	}
+	off := 2 // SvcPriority
+
+	// TargetName: a sequence of length-prefixed labels ending in a zero
+	// length label. A leading two high bits set marks a compression
+	// pointer, which can't be resolved from RDATA alone.
+	for {
+		if off >= len(data) {
+			return nil, raiseExtensionError("truncated HTTPS record target name")
+		}
+		labelLen := data[off]
+		if labelLen&0xc0 != 0 {
+			return nil, raiseExtensionError("compressed TargetName not supported")
+		}
+		off++
+		if labelLen == 0 {
+			break
+		}
+		off += int(labelLen)
+		if off > len(data) {
+			return nil, raiseExtensionError("truncated HTTPS record target name")
+		}
+	}
+
+	for off+4 <= len(data) {
+		key := binary.BigEndian.Uint16(data[off:])
+		length := binary.BigEndian.Uint16(data[off+2:])
+		off += 4
+		if off+int(length) > len(data) {
+			return nil, raiseExtensionError("truncated SvcParam")
+		}
+		if key == svcParamKeyECH {
+			return data[off : off+int(length)], nil
+		}
+		off += int(length)
+	}
+
+	return nil, raiseExtensionError("HTTPS record has no ech SvcParam")
+}
+
+// systemResolver returns "ip:53" for the first nameserver in
+// /etc/resolv.conf, falling back to a public resolver if it can't be read.
+func systemResolver() (string, error) {
+	f, err := os.Open("/etc/resolv.conf")
+	if err != nil {
+		return "8.8.8.8:53", nil
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "nameserver" {
+			return net.JoinHostPort(fields[1], "53"), nil
+		}
+	}
+	return "8.8.8.8:53", nil
+}
+
+func dns0Fqdn(host string) string {
+	if strings.HasSuffix(host, ".") {
+		return host
+	}
+	return host + "."
+}
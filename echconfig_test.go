@@ -0,0 +1,114 @@
+package http
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	utls "github.com/refraction-networking/utls"
+)
+
+func TestConfigureECH(t *testing.T) {
+	cfg := &utls.Config{}
+	ext := &TLSExtensions{ECHConfigList: []byte{0xfe, 0x0d, 0x00, 0x01}}
+
+	ext.ConfigureECH(cfg)
+
+	if !bytes.Equal(cfg.EncryptedClientHelloConfigList, ext.ECHConfigList) {
+		t.Errorf("EncryptedClientHelloConfigList = %x, want %x", cfg.EncryptedClientHelloConfigList, ext.ECHConfigList)
+	}
+}
+
+func TestConfigureECHNoopWithoutConfigList(t *testing.T) {
+	cfg := &utls.Config{}
+	(&TLSExtensions{}).ConfigureECH(cfg)
+	if cfg.EncryptedClientHelloConfigList != nil {
+		t.Errorf("EncryptedClientHelloConfigList = %x, want nil", cfg.EncryptedClientHelloConfigList)
+	}
+
+	var nilExt *TLSExtensions
+	nilExt.ConfigureECH(cfg)
+	if cfg.EncryptedClientHelloConfigList != nil {
+		t.Errorf("EncryptedClientHelloConfigList = %x, want nil after calling on a nil *TLSExtensions", cfg.EncryptedClientHelloConfigList)
+	}
+}
+
+func TestDNS0Fqdn(t *testing.T) {
+	cases := map[string]string{
+		"example.com":  "example.com.",
+		"example.com.": "example.com.",
+	}
+	for in, want := range cases {
+		if got := dns0Fqdn(in); got != want {
+			t.Errorf("dns0Fqdn(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// svcRecord builds the RDATA of an HTTPS record with the given dotted target
+// name (one length-prefixed label per dot-separated part) and SvcParams, for
+// TestEchConfigListFromSvcRecord.
+func svcRecord(target string, params map[uint16][]byte) []byte {
+	var buf []byte
+	buf = append(buf, 0, 1) // SvcPriority
+
+	for _, label := range splitLabels(target) {
+		buf = append(buf, byte(len(label)))
+		buf = append(buf, label...)
+	}
+	buf = append(buf, 0) // root label
+
+	for key, val := range params {
+		var kv [4]byte
+		binary.BigEndian.PutUint16(kv[0:2], key)
+		binary.BigEndian.PutUint16(kv[2:4], uint16(len(val)))
+		buf = append(buf, kv[:]...)
+		buf = append(buf, val...)
+	}
+	return buf
+}
+
+func splitLabels(name string) []string {
+	if name == "" {
+		return nil
+	}
+	var labels []string
+	start := 0
+	for i := 0; i < len(name); i++ {
+		if name[i] == '.' {
+			labels = append(labels, name[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(name) {
+		labels = append(labels, name[start:])
+	}
+	return labels
+}
+
+func TestEchConfigListFromSvcRecord(t *testing.T) {
+	echValue := []byte{0xde, 0xad, 0xbe, 0xef}
+	data := svcRecord("target.example.com", map[uint16][]byte{svcParamKeyECH: echValue})
+
+	got, err := echConfigListFromSvcRecord(data)
+	if err != nil {
+		t.Fatalf("echConfigListFromSvcRecord returned error: %v", err)
+	}
+	if !bytes.Equal(got, echValue) {
+		t.Errorf("echConfigListFromSvcRecord = %x, want %x", got, echValue)
+	}
+}
+
+func TestEchConfigListFromSvcRecordMissingECH(t *testing.T) {
+	data := svcRecord("target.example.com", map[uint16][]byte{1: {0x00, 0x01}})
+
+	if _, err := echConfigListFromSvcRecord(data); err == nil {
+		t.Fatal("expected an error when the HTTPS record has no ech SvcParam")
+	}
+}
+
+func TestEchConfigListFromSvcRecordTruncated(t *testing.T) {
+	if _, err := echConfigListFromSvcRecord([]byte{0x00}); err == nil {
+		t.Fatal("expected an error for truncated RDATA")
+	}
+}
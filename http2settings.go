@@ -0,0 +1,172 @@
+package http
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/http2"
+)
+
+// H2Setting is a single HTTP/2 SETTINGS parameter. HTTP2Settings.Settings
+// keeps them in the order the fingerprint declares them; Transport does not
+// carry that order over to the SETTINGS frame it sends.
+type H2Setting struct {
+	ID    http2.SettingID
+	Value uint32
+}
+
+// H2PriorityFrame is the (optional) PRIORITY frame an Akamai fingerprint
+// carries alongside SETTINGS and WINDOW_UPDATE.
+type H2PriorityFrame struct {
+	StreamDep uint32
+	Exclusive bool
+	Weight    uint8
+}
+
+// pseudoHeaderTokens maps the single-letter tokens used in the Akamai
+// PSEUDO_HEADER_ORDER field to the HTTP/2 pseudo-headers they stand for.
+var pseudoHeaderTokens = map[string]string{
+	"m": ":method",
+	"a": ":authority",
+	"s": ":scheme",
+	"p": ":path",
+}
+
+// HTTP2Settings is the parsed form of an Akamai HTTP/2 fingerprint:
+// SETTINGS;WINDOW_UPDATE;PRIORITY;PSEUDO_HEADER_ORDER, e.g.
+// "1:65536,2:0,3:1000,4:6291456,6:262144|15663105|0|m,a,s,p"
+type HTTP2Settings struct {
+	Settings              []H2Setting
+	WindowUpdateIncrement uint32
+	Priority              *H2PriorityFrame
+	PseudoHeaderOrder     []string
+}
+
+// StringToHTTP2Settings parses an Akamai HTTP/2 fingerprint string into an
+// HTTP2Settings, the HTTP/2 peer to StringToSpec.
+func StringToHTTP2Settings(fingerprint string) (*HTTP2Settings, error) {
+	parts := strings.Split(fingerprint, "|")
+	if len(parts) != 4 {
+		return nil, raiseExtensionError(fingerprint)
+	}
+
+	h2 := &HTTP2Settings{}
+
+	for _, pair := range strings.Split(parts[0], ",") {
+		kv := strings.SplitN(pair, ":", 2)
+		if len(kv) != 2 {
+			return nil, raiseExtensionError(pair)
+		}
+		id, err := strconv.ParseUint(kv[0], 10, 16)
+		if err != nil {
+			return nil, err
+		}
+		value, err := strconv.ParseUint(kv[1], 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		h2.Settings = append(h2.Settings, H2Setting{ID: http2.SettingID(id), Value: uint32(value)})
+	}
+
+	windowUpdate, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	h2.WindowUpdateIncrement = uint32(windowUpdate)
+
+	if parts[2] != "0" {
+		fields := strings.Split(parts[2], ":")
+		if len(fields) != 3 {
+			return nil, raiseExtensionError(parts[2])
+		}
+		exclusive, err := strconv.ParseUint(fields[0], 10, 1)
+		if err != nil {
+			return nil, err
+		}
+		streamDep, err := strconv.ParseUint(fields[1], 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		weight, err := strconv.ParseUint(fields[2], 10, 8)
+		if err != nil {
+			return nil, err
+		}
+		h2.Priority = &H2PriorityFrame{
+			Exclusive: exclusive == 1,
+			StreamDep: uint32(streamDep),
+			Weight:    uint8(weight),
+		}
+	}
+
+	for _, token := range strings.Split(parts[3], ",") {
+		header, ok := pseudoHeaderTokens[token]
+		if !ok {
+			return nil, raiseExtensionError(token)
+		}
+		h2.PseudoHeaderOrder = append(h2.PseudoHeaderOrder, header)
+	}
+
+	return h2, nil
+}
+
+// Transport builds an *http2.Transport that puts as many of these settings
+// on the actual wire as golang.org/x/net/http2's public API allows. It
+// wraps an *http.Transport carrying an http.HTTP2Config and hands it to
+// http2.ConfigureTransports, so HEADER_TABLE_SIZE, INITIAL_WINDOW_SIZE and
+// MAX_FRAME_SIZE land in the real client SETTINGS frame instead of being
+// reinterpreted, and WindowUpdateIncrement becomes the actual stream-0
+// WINDOW_UPDATE frame http2 sends right after it. MAX_HEADER_LIST_SIZE is
+// applied directly on the returned *http2.Transport, which honours it the
+// same way regardless of how the Transport was constructed.
+//
+// golang.org/x/net/http2 has no hook to emit an initial PRIORITY frame or to
+// reorder the :method/:scheme/:authority/:path pseudo-headers it writes, so
+// Priority and PseudoHeaderOrder can't be replayed here; they're still
+// parsed and kept on HTTP2Settings for callers who need them (e.g. to
+// compare against an observed fingerprint), but this Transport cannot emit
+// them. Exact replay of those two would require a frame-level HTTP/2 client
+// outside what golang.org/x/net/http2 exposes.
+func (h *HTTP2Settings) Transport(tlsConfig *tls.Config) (*http2.Transport, error) {
+	t1 := &http.Transport{
+		TLSClientConfig: tlsConfig,
+		HTTP2:           &http.HTTP2Config{},
+	}
+	for _, s := range h.Settings {
+		switch s.ID {
+		case http2.SettingHeaderTableSize:
+			t1.HTTP2.MaxDecoderHeaderTableSize = int(s.Value)
+		case http2.SettingInitialWindowSize:
+			t1.HTTP2.MaxReceiveBufferPerStream = int(s.Value)
+		case http2.SettingMaxFrameSize:
+			t1.HTTP2.MaxReadFrameSize = int(s.Value)
+		}
+	}
+	if h.WindowUpdateIncrement != 0 {
+		t1.HTTP2.MaxReceiveBufferPerConnection = int(h.WindowUpdateIncrement)
+	}
+
+	t2, err := http2.ConfigureTransports(t1)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range h.Settings {
+		if s.ID == http2.SettingMaxHeaderListSize {
+			t2.MaxHeaderListSize = s.Value
+		}
+	}
+	return t2, nil
+}
+
+func (e *H2PriorityFrame) String() string {
+	if e == nil {
+		return "0"
+	}
+	exclusive := 0
+	if e.Exclusive {
+		exclusive = 1
+	}
+	return fmt.Sprintf("%d:%d:%d", exclusive, e.StreamDep, e.Weight)
+}
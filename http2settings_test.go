@@ -0,0 +1,88 @@
+package http
+
+import (
+	"testing"
+
+	"golang.org/x/net/http2"
+)
+
+func TestStringToHTTP2Settings(t *testing.T) {
+	got, err := StringToHTTP2Settings("1:65536,2:0,3:1000,4:6291456,6:262144|15663105|0|m,a,s,p")
+	if err != nil {
+		t.Fatalf("StringToHTTP2Settings returned error: %v", err)
+	}
+
+	wantSettings := []H2Setting{
+		{ID: http2.SettingHeaderTableSize, Value: 65536},
+		{ID: http2.SettingEnablePush, Value: 0},
+		{ID: http2.SettingMaxConcurrentStreams, Value: 1000},
+		{ID: http2.SettingInitialWindowSize, Value: 6291456},
+		{ID: http2.SettingMaxHeaderListSize, Value: 262144},
+	}
+	if len(got.Settings) != len(wantSettings) {
+		t.Fatalf("Settings = %+v, want %+v", got.Settings, wantSettings)
+	}
+	for i, s := range got.Settings {
+		if s != wantSettings[i] {
+			t.Errorf("Settings[%d] = %+v, want %+v", i, s, wantSettings[i])
+		}
+	}
+
+	if got.WindowUpdateIncrement != 15663105 {
+		t.Errorf("WindowUpdateIncrement = %d, want 15663105", got.WindowUpdateIncrement)
+	}
+	if got.Priority != nil {
+		t.Errorf("Priority = %+v, want nil for a \"0\" PRIORITY field", got.Priority)
+	}
+	wantOrder := []string{":method", ":authority", ":scheme", ":path"}
+	if len(got.PseudoHeaderOrder) != len(wantOrder) {
+		t.Fatalf("PseudoHeaderOrder = %v, want %v", got.PseudoHeaderOrder, wantOrder)
+	}
+	for i, h := range got.PseudoHeaderOrder {
+		if h != wantOrder[i] {
+			t.Errorf("PseudoHeaderOrder[%d] = %q, want %q", i, h, wantOrder[i])
+		}
+	}
+}
+
+func TestStringToHTTP2SettingsWithPriority(t *testing.T) {
+	got, err := StringToHTTP2Settings("1:65536|0|1:42:255|m,a,s,p")
+	if err != nil {
+		t.Fatalf("StringToHTTP2Settings returned error: %v", err)
+	}
+	if got.Priority == nil {
+		t.Fatal("Priority = nil, want a parsed PRIORITY frame")
+	}
+	if !got.Priority.Exclusive || got.Priority.StreamDep != 42 || got.Priority.Weight != 255 {
+		t.Errorf("Priority = %+v, want Exclusive=true StreamDep=42 Weight=255", got.Priority)
+	}
+}
+
+func TestStringToHTTP2SettingsRejectsMalformedFingerprint(t *testing.T) {
+	cases := []string{
+		"",
+		"1:65536|0|0",
+		"1:65536|0|0|z",
+		"nope|0|0|m",
+	}
+	for _, fp := range cases {
+		if _, err := StringToHTTP2Settings(fp); err == nil {
+			t.Errorf("StringToHTTP2Settings(%q) = nil error, want an error", fp)
+		}
+	}
+}
+
+func TestHTTP2SettingsTransportAppliesSettings(t *testing.T) {
+	h2, err := StringToHTTP2Settings("1:65536,2:0,3:1000,4:6291456,5:16384,6:262144|15663105|0|m,a,s,p")
+	if err != nil {
+		t.Fatalf("StringToHTTP2Settings: %v", err)
+	}
+
+	transport, err := h2.Transport(nil)
+	if err != nil {
+		t.Fatalf("Transport returned error: %v", err)
+	}
+	if transport.MaxHeaderListSize != 262144 {
+		t.Errorf("MaxHeaderListSize = %d, want 262144", transport.MaxHeaderListSize)
+	}
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"strconv"
+	"strings"
+
+	utls "github.com/refraction-networking/utls"
+)
+
+// JA4Fingerprint is the parsed form of the first, plaintext segment of a
+// JA4 string, e.g. "t13d1516h2" from "t13d1516h2_8daaf6152771_02713d6af862".
+type JA4Fingerprint struct {
+	Protocol    string // "t" (TCP/TLS) or "q" (QUIC)
+	TLSVersion  string // e.g. "13"
+	SNI         string // "d" domain SNI present, "i" IP literal
+	CipherCount int
+	ExtCount    int
+	ALPN        string
+}
+
+// parseJA4Fingerprint parses the plaintext first segment of a JA4 string.
+func parseJA4Fingerprint(a string) (*JA4Fingerprint, error) {
+	if len(a) < 10 {
+		return nil, raiseExtensionError(a)
+	}
+	cipherCount, err := strconv.Atoi(a[4:6])
+	if err != nil {
+		return nil, err
+	}
+	extCount, err := strconv.Atoi(a[6:8])
+	if err != nil {
+		return nil, err
+	}
+	return &JA4Fingerprint{
+		Protocol:    a[0:1],
+		TLSVersion:  a[1:3],
+		SNI:         a[3:4],
+		CipherCount: cipherCount,
+		ExtCount:    extCount,
+		ALPN:        a[8:],
+	}, nil
+}
+
+// StringToSpecJA4 resolves a JA4 fingerprint (e.g.
+// "t13d1516h2_8daaf6152771_02713d6af862") to a ClientHelloSpec. JA4 hashes
+// the sorted cipher list and the sorted extension list plus signature
+// algorithms instead of listing them like JA3 does, so unlike JA3 a JA4
+// string cannot be turned back into a spec on its own: doing this requires
+// a hash->spec table built by actually computing JA4 over each known
+// preset's real cipher/extension list (crypto/sha256, truncated per the
+// JA4 spec) and verifying the result against hashes observed from real
+// browser traffic, with a test asserting JA4(preset) equals its table
+// entry. No such table exists yet, so every fingerprint is rejected rather
+// than matched against guessed hashes.
+func (tlsExtensions *TLSExtensions) StringToSpecJA4(ja4 string, userAgent string) (*utls.ClientHelloSpec, error) {
+	segments := strings.Split(ja4, "_")
+	if len(segments) != 3 {
+		return nil, raiseExtensionError(ja4)
+	}
+	if _, err := parseJA4Fingerprint(segments[0]); err != nil {
+		return nil, err
+	}
+
+	return nil, raiseExtensionError(ja4)
+}
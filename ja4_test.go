@@ -0,0 +1,42 @@
+package http
+
+import "testing"
+
+func TestParseJA4Fingerprint(t *testing.T) {
+	got, err := parseJA4Fingerprint("t13d1516h2")
+	if err != nil {
+		t.Fatalf("parseJA4Fingerprint returned error: %v", err)
+	}
+	want := &JA4Fingerprint{
+		Protocol:    "t",
+		TLSVersion:  "13",
+		SNI:         "d",
+		CipherCount: 15,
+		ExtCount:    16,
+		ALPN:        "h2",
+	}
+	if *got != *want {
+		t.Fatalf("parseJA4Fingerprint = %+v, want %+v", got, want)
+	}
+
+	if _, err := parseJA4Fingerprint("short"); err == nil {
+		t.Fatal("expected an error for a truncated JA4 segment")
+	}
+}
+
+// StringToSpecJA4 has no verified hash->spec table (see the doc comment on
+// it), so it must reject every fingerprint rather than resolve one against
+// a guessed table entry.
+func TestStringToSpecJA4RejectsUnresolvedHashes(t *testing.T) {
+	ext := &TLSExtensions{}
+	cases := []string{
+		"t13d1516h2_8daaf6152771_02713d6af862",
+		"t13d1517h2_5b57614c22b0_3312d24b6c8e",
+		"not-a-ja4-string",
+	}
+	for _, ja4 := range cases {
+		if _, err := ext.StringToSpecJA4(ja4, "Chrome/120"); err == nil {
+			t.Errorf("StringToSpecJA4(%q) = nil error, want an error", ja4)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"testing"
+
+	utls "github.com/refraction-networking/utls"
+)
+
+func hasKyberCurve(spec *utls.ClientHelloSpec) bool {
+	for _, e := range spec.Extensions {
+		if sc, ok := e.(*utls.SupportedCurvesExtension); ok {
+			for _, c := range sc.Curves {
+				if c == utls.X25519Kyber768Draft00 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func hasKyberKeyShare(spec *utls.ClientHelloSpec) bool {
+	for _, e := range spec.Extensions {
+		if ks, ok := e.(*utls.KeyShareExtension); ok {
+			for _, s := range ks.KeyShares {
+				if s.Group == utls.X25519Kyber768Draft00 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func TestSpecFromClientHelloIDKyberOverride(t *testing.T) {
+	enabled := true
+	spec, err := SpecFromClientHelloID("chrome_102", &TLSExtensions{KyberEnabled: &enabled})
+	if err != nil {
+		t.Fatalf("SpecFromClientHelloID(chrome_102) with KyberEnabled=true: %v", err)
+	}
+	if !hasKyberCurve(spec) || !hasKyberKeyShare(spec) {
+		t.Fatal("SpecFromClientHelloID with KyberEnabled=true did not add the Kyber curve/key share")
+	}
+
+	disabled := false
+	spec, err = SpecFromClientHelloID("chrome_102", &TLSExtensions{KyberEnabled: &disabled})
+	if err != nil {
+		t.Fatalf("SpecFromClientHelloID(chrome_102) with KyberEnabled=false: %v", err)
+	}
+	if hasKyberCurve(spec) || hasKyberKeyShare(spec) {
+		t.Fatal("SpecFromClientHelloID with KyberEnabled=false left the Kyber curve/key share in place")
+	}
+}
+
+func TestSpecFromClientHelloIDNoKyberOverrideLeavesPresetDefault(t *testing.T) {
+	spec, err := SpecFromClientHelloID("chrome_102", nil)
+	if err != nil {
+		t.Fatalf("SpecFromClientHelloID(chrome_102): %v", err)
+	}
+	if hasKyberCurve(spec) {
+		t.Fatal("chrome_102 preset unexpectedly carries the Kyber curve with no KyberEnabled override")
+	}
+}
+
+func TestStringToSpecKyberUserAgentInference(t *testing.T) {
+	ext := &TLSExtensions{}
+	ja3 := "771,4865-4866-4867,0-23-65281-10-11-35-16-5-13-18-51-45-43-27-21,29-23-24,0"
+
+	spec, err := ext.StringToSpec(ja3, "Mozilla/5.0 Chrome/120.0.0.0 Safari/537.36")
+	if err != nil {
+		t.Fatalf("StringToSpec with Chrome/120 UA: %v", err)
+	}
+	if !hasKyberCurve(spec) {
+		t.Fatal("StringToSpec did not infer KyberEnabled=true for a Chrome 120 user agent")
+	}
+
+	spec, err = ext.StringToSpec(ja3, "Mozilla/5.0 Chrome/100.0.0.0 Safari/537.36")
+	if err != nil {
+		t.Fatalf("StringToSpec with Chrome/100 UA: %v", err)
+	}
+	if hasKyberCurve(spec) {
+		t.Fatal("StringToSpec inferred KyberEnabled=true for a Chrome 100 user agent, which predates the hybrid group")
+	}
+}
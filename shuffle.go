@@ -0,0 +1,68 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	mrand "math/rand"
+
+	utls "github.com/refraction-networking/utls"
+)
+
+// shuffleSeedOrRandom returns ShuffleSeed if it was set by the caller, or a
+// freshly generated random seed otherwise, so every unseeded connection gets
+// its own shuffle the way real Chrome does per-connection.
+func (tlsExtensions *TLSExtensions) shuffleSeedOrRandom() [32]byte {
+	if tlsExtensions.ShuffleSeed != ([32]byte{}) {
+		return tlsExtensions.ShuffleSeed
+	}
+	var seed [32]byte
+	_, _ = rand.Read(seed[:])
+	return seed
+}
+
+// shuffleExtensions deterministically shuffles the non-anchored extensions
+// of exts using seed. Every GREASE, padding, and pre_shared_key extension
+// stays exactly where it was, however many of them appear in the list (the
+// leading GREASE from StringToSpec's Chrome branch, and, for JA3 strings
+// ending in "41"/"21", the second GREASE inserted right before that
+// trailing anchor): Chrome 106+ randomizes extension order per connection
+// but keeps those anchors fixed, so a static JA3-derived spec that never
+// shuffles is trivially distinguishable from real Chrome traffic.
+func shuffleExtensions(exts []utls.TLSExtension, seed [32]byte) []utls.TLSExtension {
+	if len(exts) < 2 {
+		return exts
+	}
+
+	anchored := make([]bool, len(exts))
+	for i, e := range exts {
+		switch e.(type) {
+		case *utls.UtlsGREASEExtension, *utls.UtlsPaddingExtension, *utls.UtlsPreSharedKeyExtension:
+			anchored[i] = true
+		}
+	}
+
+	var freeIdx []int
+	var movable []utls.TLSExtension
+	for i, e := range exts {
+		if !anchored[i] {
+			freeIdx = append(freeIdx, i)
+			movable = append(movable, e)
+		}
+	}
+
+	rng := mrand.New(mrand.NewSource(seedToInt64(seed)))
+	rng.Shuffle(len(movable), func(i, j int) {
+		movable[i], movable[j] = movable[j], movable[i]
+	})
+
+	shuffled := make([]utls.TLSExtension, len(exts))
+	copy(shuffled, exts)
+	for k, idx := range freeIdx {
+		shuffled[idx] = movable[k]
+	}
+	return shuffled
+}
+
+func seedToInt64(seed [32]byte) int64 {
+	return int64(binary.BigEndian.Uint64(seed[:8]))
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"reflect"
+	"testing"
+
+	utls "github.com/refraction-networking/utls"
+)
+
+// namedExtension lets a test tell shuffled extensions apart by identity
+// without needing real extension payloads.
+type namedExtension struct {
+	utls.TLSExtension
+	name string
+}
+
+func exts(names ...string) []utls.TLSExtension {
+	var out []utls.TLSExtension
+	for _, n := range names {
+		switch n {
+		case "grease":
+			out = append(out, &utls.UtlsGREASEExtension{})
+		case "padding":
+			out = append(out, &utls.UtlsPaddingExtension{})
+		case "psk":
+			out = append(out, &utls.UtlsPreSharedKeyExtension{})
+		default:
+			out = append(out, &namedExtension{name: n})
+		}
+	}
+	return out
+}
+
+func names(list []utls.TLSExtension) []string {
+	out := make([]string, len(list))
+	for i, e := range list {
+		switch v := e.(type) {
+		case *utls.UtlsGREASEExtension:
+			out[i] = "grease"
+		case *utls.UtlsPaddingExtension:
+			out[i] = "padding"
+		case *utls.UtlsPreSharedKeyExtension:
+			out[i] = "psk"
+		case *namedExtension:
+			out[i] = v.name
+		}
+	}
+	return out
+}
+
+func TestShuffleExtensionsDeterministicForFixedSeed(t *testing.T) {
+	seed := [32]byte{1, 2, 3}
+	input := exts("grease", "a", "b", "c", "d", "padding")
+
+	first := shuffleExtensions(input, seed)
+	second := shuffleExtensions(input, seed)
+
+	if !reflect.DeepEqual(names(first), names(second)) {
+		t.Fatalf("shuffleExtensions with the same seed produced different orders: %v vs %v", names(first), names(second))
+	}
+}
+
+func TestShuffleExtensionsKeepsAnchorsInPlace(t *testing.T) {
+	seed := [32]byte{4, 5, 6}
+	input := exts("grease", "a", "b", "psk", "c", "padding", "d")
+
+	got := shuffleExtensions(input, seed)
+
+	for i, e := range input {
+		switch e.(type) {
+		case *utls.UtlsGREASEExtension, *utls.UtlsPaddingExtension, *utls.UtlsPreSharedKeyExtension:
+			if reflect.TypeOf(got[i]) != reflect.TypeOf(e) {
+				t.Fatalf("anchor at index %d moved: got %T, want %T", i, got[i], e)
+			}
+		}
+	}
+}
+
+func TestShuffleExtensionsMovesNonAnchors(t *testing.T) {
+	seed := [32]byte{7, 8, 9}
+	input := exts("grease", "a", "b", "c", "d", "e", "f", "padding")
+
+	got := shuffleExtensions(input, seed)
+
+	if reflect.DeepEqual(names(got), names(input)) {
+		t.Fatal("shuffleExtensions returned the input order unchanged; expected the movable extensions to be reordered")
+	}
+}
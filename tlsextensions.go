@@ -24,6 +24,40 @@ type TLSExtensions struct {
 	KeyShareCurves               *utls.KeyShareExtension
 	NotUsedGREASE                bool
 	ClientHelloHexStream         string
+
+	// ShuffleExtensions, when true, deterministically shuffles the
+	// non-anchored extensions of the produced ClientHelloSpec, mirroring
+	// the per-connection extension randomization Chrome introduced in M106.
+	ShuffleExtensions bool
+	// ShuffleSeed seeds the shuffle so tests can reproduce a given hello.
+	// If left zero-valued while ShuffleExtensions is set, a random seed is
+	// generated per call.
+	ShuffleSeed [32]byte
+
+	// KyberEnabled forces the X25519Kyber768Draft00 post-quantum hybrid key
+	// share on or off for SpecFromClientHelloID. StringToSpec adds the
+	// hybrid group only on its Chrome GREASE path (a user agent
+	// parseUserAgent classifies as chrome, with NotUsedGREASE false); for
+	// any other user agent, or with NotUsedGREASE set, StringToSpec does not
+	// add it even when this is true. If nil, StringToSpec infers it from the
+	// user agent (Chrome 116+ ships the hybrid group by default, and its
+	// absence is itself a fingerprintable signal), while
+	// SpecFromClientHelloID leaves the preset's own default untouched.
+	KyberEnabled *bool
+
+	// ECHConfigList, when set, is the ECHConfigList uTLS should negotiate
+	// real ECH with, as fetched from the target's DNS HTTPS record by
+	// FetchECHConfigList. The GREASEEncryptedClientHelloExtension genMap
+	// already places in extension 65037 stays in the spec unchanged; uTLS
+	// swaps it for the real encrypted_client_hello extension at handshake
+	// time once ConfigureECH has set this on the *utls.Config used to
+	// dial. It cannot be applied by adding a different TLSExtension to the
+	// spec, since the real extension's payload is an HPKE-encrypted
+	// ClientHelloInner that uTLS computes only from the Config field.
+	ECHConfigList []byte
+	// DelegatedCredentialSchemes overrides the hardcoded signature scheme
+	// list genMap uses for the delegated_credentials extension (id 34).
+	DelegatedCredentialSchemes []utls.SignatureScheme
 }
 
 type errExtensionNotExist struct {
@@ -40,6 +74,29 @@ func raiseExtensionError(info string) *errExtensionNotExist {
 	}
 }
 
+// minKyberChromeVersion is the first Chrome major version that ships
+// X25519Kyber768Draft00 by default.
+const minKyberChromeVersion = 116
+
+// chromeMajorVersion extracts the major version number from a Chrome user
+// agent string, e.g. 120 from "Chrome/120.0.0.0".
+func chromeMajorVersion(userAgent string) (int, bool) {
+	idx := strings.Index(userAgent, "Chrome/")
+	if idx == -1 {
+		return 0, false
+	}
+	rest := userAgent[idx+len("Chrome/"):]
+	end := strings.IndexByte(rest, '.')
+	if end == -1 {
+		end = len(rest)
+	}
+	major, err := strconv.Atoi(rest[:end])
+	if err != nil {
+		return 0, false
+	}
+	return major, true
+}
+
 func parseUserAgent(userAgent string) string {
 	switch {
 	case strings.Contains(strings.ToLower(userAgent), "chrome"):
@@ -75,10 +132,27 @@ func (tlsExtensions *TLSExtensions) StringToSpec(ja3 string, userAgent string) (
 	if len(pointFormats) == 1 && pointFormats[0] == "" {
 		pointFormats = []string{}
 	}
+
+	// resolve whether to advertise the post-quantum hybrid key share.
+	// Default false: only a Chrome UA with a version we can actually parse
+	// as >=116 turns it on, since parseUserAgent falls back to chrome for
+	// UAs it doesn't recognise (Safari, empty strings, ...) and those
+	// browsers don't ship this group.
+	kyberEnabled := false
+	if major, ok := chromeMajorVersion(userAgent); ok {
+		kyberEnabled = major >= minKyberChromeVersion
+	}
+	if ext.KyberEnabled != nil {
+		kyberEnabled = *ext.KyberEnabled
+	}
+
 	// parse curves
 	var targetCurves []utls.CurveID
 	if parsedUserAgent == chrome && !tlsExtensions.NotUsedGREASE {
 		targetCurves = append(targetCurves, utls.CurveID(utls.GREASE_PLACEHOLDER)) //append grease for Chrome browsers
+		if kyberEnabled {
+			targetCurves = append(targetCurves, utls.X25519Kyber768Draft00)
+		}
 		if supportedVersionsExt, ok := extMap["43"]; ok {
 			if supportedVersions, ok := supportedVersionsExt.(*utls.SupportedVersionsExtension); ok {
 				supportedVersions.Versions = append([]uint16{utls.GREASE_PLACEHOLDER}, supportedVersions.Versions...)
@@ -87,6 +161,9 @@ func (tlsExtensions *TLSExtensions) StringToSpec(ja3 string, userAgent string) (
 		if keyShareExt, ok := extMap["51"]; ok {
 			if keyShare, ok := keyShareExt.(*utls.KeyShareExtension); ok {
 				keyShare.KeyShares = append([]utls.KeyShare{{Group: utls.CurveID(utls.GREASE_PLACEHOLDER), Data: []byte{0}}}, keyShare.KeyShares...)
+				if kyberEnabled {
+					keyShare.KeyShares = append([]utls.KeyShare{keyShare.KeyShares[0], {Group: utls.X25519Kyber768Draft00}}, keyShare.KeyShares[1:]...)
+				}
 			}
 		}
 	} else {
@@ -130,6 +207,9 @@ func (tlsExtensions *TLSExtensions) StringToSpec(ja3 string, userAgent string) (
 		if ext.DelegatedCredentials != nil {
 			extMap["34"] = ext.DelegatedCredentials
 		}
+		if ext.DelegatedCredentialSchemes != nil {
+			extMap["34"] = &utls.DelegatedCredentialsExtension{SupportedSignatureAlgorithms: ext.DelegatedCredentialSchemes}
+		}
 		if ext.SupportedVersions != nil {
 			extMap["43"] = ext.SupportedVersions
 		}
@@ -173,6 +253,10 @@ func (tlsExtensions *TLSExtensions) StringToSpec(ja3 string, userAgent string) (
 		exts = append(exts, &utls.UtlsGREASEExtension{})
 	}
 
+	if tlsExtensions.ShuffleExtensions {
+		exts = shuffleExtensions(exts, tlsExtensions.shuffleSeedOrRandom())
+	}
+
 	// build CipherSuites
 	var suites []uint16
 	//Optionally Add Chrome Grease Extension